@@ -0,0 +1,67 @@
+package common
+
+import (
+	"math/big"
+
+	dexoncommon "github.com/dexon-foundation/dexon/common"
+)
+
+// Contract describes the minimal caller-facing view of the contract
+// invoking the running instruction stream: who called it and with what
+// calldata.
+type Contract struct {
+	CallerAddress dexoncommon.Address
+	Input         []byte
+}
+
+// Storage is the subset of state access the runtime's built-ins need.
+type Storage interface {
+	GetNonce(addr dexoncommon.Address) uint64
+}
+
+// Context carries the per-call execution environment threaded through
+// every runtime built-in: block/tx metadata, state access, and the
+// pluggable RandSource RAND/VRAND/RANDRANGE mix their domain-separation
+// parts through.
+type Context struct {
+	BlockNumber *big.Int
+	Time        *big.Int
+	Coinbase    dexoncommon.Address
+	GasLimit    uint64
+	GetHash     func(uint64) dexoncommon.Hash
+
+	Contract *Contract
+	Origin   dexoncommon.Address
+	Storage  Storage
+
+	// Randomness is the round's randomness beacon output, derived from
+	// the DEXON consensus layer's BLS threshold signature; RAND and
+	// VRAND both mix it into their digest so the result is bound to
+	// that round's verifiable signature rather than tx-local state
+	// alone.
+	Randomness []byte
+	// RandCallIndex disambiguates successive RAND/VRAND/RANDRANGE calls
+	// within the same transaction so they don't collide on the same
+	// digest; each call increments it.
+	RandCallIndex uint64
+	// RandSource is the source RAND/VRAND/RANDRANGE mix their
+	// domain-separation parts through. A nil RandSource falls back to
+	// KeccakRandSource, preserving the original Keccak256-based
+	// derivation, so existing callers that don't set it are unaffected.
+	RandSource RandSource
+}
+
+// randSourceOrDefault returns ctx.RandSource, or KeccakRandSource{} when
+// the caller left it unset.
+func (ctx *Context) randSourceOrDefault() RandSource {
+	if ctx.RandSource != nil {
+		return ctx.RandSource
+	}
+	return KeccakRandSource{}
+}
+
+// RandSeed mixes parts through ctx's RandSource, the single entry point
+// RAND/VRAND/RANDRANGE all share.
+func (ctx *Context) RandSeed(parts ...[]byte) []byte {
+	return ctx.randSourceOrDefault().Seed(parts...)
+}
@@ -0,0 +1,27 @@
+package common
+
+import "github.com/dexon-foundation/dexon/crypto"
+
+// RandSource abstracts the bytes mixed into SQLVM's randomness built-ins.
+// Splitting it out of the opcode dispatch table lets tests inject a
+// deterministic source and lets a future consensus upgrade swap in a real
+// VRF (e.g. one backed by the round's BLS threshold signature) without
+// touching RAND/VRAND themselves.
+//
+// RAND/VRAND/RANDRANGE reach this through the RandSource field on
+// Context (see context.go), mixed in via Context.RandSeed — not a
+// package-level variable — so swapping it out never races across
+// concurrent executions that share the same process.
+type RandSource interface {
+	// Seed mixes the given domain-separated parts into a single digest.
+	Seed(parts ...[]byte) []byte
+}
+
+// KeccakRandSource is the default RandSource, preserving RAND's original
+// Keccak256-based derivation.
+type KeccakRandSource struct{}
+
+// Seed implements RandSource.
+func (KeccakRandSource) Seed(parts ...[]byte) []byte {
+	return crypto.Keccak256(parts...)
+}
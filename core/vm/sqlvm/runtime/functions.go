@@ -1,9 +1,14 @@
 package runtime
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"math"
+	"math/big"
+
+	"golang.org/x/crypto/ripemd160"
 
 	"github.com/dexon-foundation/decimal"
 
@@ -32,6 +37,54 @@ const (
 	BITNOT
 	OCTETLENGTH
 	SUBSTRING
+	ABIENCODE
+	ABIDECODE
+	KECCAK256
+	SHA256
+	RIPEMD160
+	ECRECOVER
+	CONCAT
+	LPAD
+	RPAD
+	UPPER
+	LOWER
+	REPLACE
+	SHL
+	SHR
+	SAR
+	ROL
+	ROR
+	VRAND
+	RANDRANGE
+	SUM
+	COUNT
+	AVG
+	MIN
+	MAX
+	GROUPCONCAT
+)
+
+// Gas costs for the aggregate built-ins, linear in the number of rows
+// consumed from the operand column.
+const (
+	GasAggregateBase   = 10
+	GasAggregatePerRow = 3
+)
+
+// avgScale is the decimal scale AVG divides with.
+const avgScale = 18
+
+// Gas costs for the cryptographic built-ins, mirroring the EVM opcode and
+// precompile costs they expose (go-ethereum's Keccak256Gas/Sha256BaseGas/
+// Ripemd160BaseGas/EcrecoverGas and their respective per-word costs).
+const (
+	GasKeccak256     = 30
+	GasKeccak256Word = 6
+	GasSHA256        = 60
+	GasSHA256Word    = 12
+	GasRIPEMD160     = 600
+	GasRIPEMD160Word = 120
+	GasECRecover     = 3000
 )
 
 type fn func(*common.Context, Instruction, uint64) (*Operand, error)
@@ -107,6 +160,106 @@ var (
 			Fn:      fnSubString,
 			GasFunc: constGasFunc(GasMemFree),
 		},
+		ABIENCODE: {
+			Fn:      fnABIEncode,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		ABIDECODE: {
+			Fn:      fnABIDecode,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		KECCAK256: {
+			Fn:      fnKeccak256,
+			GasFunc: gasKeccak256,
+		},
+		SHA256: {
+			Fn:      fnSHA256,
+			GasFunc: gasSHA256,
+		},
+		RIPEMD160: {
+			Fn:      fnRIPEMD160,
+			GasFunc: gasRIPEMD160,
+		},
+		ECRECOVER: {
+			Fn:      fnECRecover,
+			GasFunc: constGasFunc(GasECRecover),
+		},
+		CONCAT: {
+			Fn:      fnConcat,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		LPAD: {
+			Fn:      fnLPad,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		RPAD: {
+			Fn:      fnRPad,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		UPPER: {
+			Fn:      fnUpper,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		LOWER: {
+			Fn:      fnLower,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		REPLACE: {
+			Fn:      fnReplace,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		SHL: {
+			Fn:      fnSHL,
+			GasFunc: constGasFunc(GasBitCmp),
+		},
+		SHR: {
+			Fn:      fnSHR,
+			GasFunc: constGasFunc(GasBitCmp),
+		},
+		SAR: {
+			Fn:      fnSAR,
+			GasFunc: constGasFunc(GasBitCmp),
+		},
+		ROL: {
+			Fn:      fnROL,
+			GasFunc: constGasFunc(GasBitCmp),
+		},
+		ROR: {
+			Fn:      fnROR,
+			GasFunc: constGasFunc(GasBitCmp),
+		},
+		VRAND: {
+			Fn:      fnVRand,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		RANDRANGE: {
+			Fn:      fnRandRange,
+			GasFunc: constGasFunc(GasMemAlloc),
+		},
+		SUM: {
+			Fn:      fnSum,
+			GasFunc: gasAggregate,
+		},
+		COUNT: {
+			Fn:      fnCount,
+			GasFunc: gasAggregate,
+		},
+		AVG: {
+			Fn:      fnAvg,
+			GasFunc: gasAggregate,
+		},
+		MIN: {
+			Fn:      fnMin,
+			GasFunc: gasAggregate,
+		},
+		MAX: {
+			Fn:      fnMax,
+			GasFunc: gasAggregate,
+		},
+		GROUPCONCAT: {
+			Fn:      fnGroupConcat,
+			GasFunc: gasAggregate,
+		},
 	}
 )
 
@@ -245,7 +398,7 @@ func fnRand(ctx *common.Context, in Instruction, length uint64) (result *Operand
 		binary.PutUvarint(binaryUsedIndex, ctx.RandCallIndex)
 		ctx.RandCallIndex++
 
-		hash := crypto.Keccak256(
+		hash := ctx.RandSeed(
 			ctx.Randomness,
 			ctx.Origin.Bytes(),
 			binaryOriginNonce,
@@ -262,6 +415,122 @@ func fnRand(ctx *common.Context, in Instruction, length uint64) (result *Operand
 	return
 }
 
+// fnVRand implements VRAND(seed bytes32) -> uint256: it mixes the
+// caller-supplied seed with the same domain-separation parts as RAND
+// (the round's randomness beacon ctx.Randomness, backed by the DEXON
+// consensus layer's BLS threshold signature, plus origin, nonce and call
+// index) and the contract caller's address through ctx.RandSource, giving each
+// call site its own independently-seeded stream that is bound to that
+// round's verifiable signature rather than tx-local state alone.
+func fnVRand(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 1 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	seedOp := in.Input[0]
+	if !metaAllBytesLike(seedOp) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	binaryOriginNonce := make([]byte, binary.MaxVarintLen64)
+	binary.PutUvarint(binaryOriginNonce, ctx.Storage.GetNonce(ctx.Origin))
+
+	vType := ast.ComposeDataType(ast.DataTypeMajorUint, 31)
+	n := len(seedOp.Data)
+	result = &Operand{Meta: []ast.DataType{vType}, Data: make([]Tuple, n)}
+
+	for i := 0; i < n; i++ {
+		binaryUsedIndex := make([]byte, binary.MaxVarintLen64)
+		binary.PutUvarint(binaryUsedIndex, ctx.RandCallIndex)
+		ctx.RandCallIndex++
+
+		hash := ctx.RandSeed(
+			seedOp.Data[i][0].Bytes,
+			ctx.Randomness,
+			ctx.Contract.CallerAddress.Bytes(),
+			ctx.Origin.Bytes(),
+			binaryOriginNonce,
+			binaryUsedIndex,
+		)
+
+		var v decimal.Decimal
+		v, err = ast.DecimalDecode(vType, hash)
+		if err != nil {
+			return
+		}
+		result.Data[i] = Tuple{&Raw{Value: v}}
+	}
+	return
+}
+
+// fnRandRange implements RANDRANGE(lo, hi): a uint drawn uniformly from
+// [lo, hi) via rejection sampling over the same ctx.RandSource as
+// RAND/VRAND, avoiding the modulo bias a plain `RAND() % (hi-lo)` would
+// introduce.
+func fnRandRange(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 2 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	loOp, hiOp := in.Input[0], in.Input[1]
+	n, err := findMaxDataLength(in.Input)
+	if err != nil {
+		return
+	}
+
+	vType := ast.ComposeDataType(ast.DataTypeMajorUint, 31)
+	result = &Operand{Meta: []ast.DataType{vType}, Data: make([]Tuple, n)}
+
+	binaryOriginNonce := make([]byte, binary.MaxVarintLen64)
+	binary.PutUvarint(binaryOriginNonce, ctx.Storage.GetNonce(ctx.Origin))
+
+	for i := 0; i < n; i++ {
+		lo := loOp.Data[i%len(loOp.Data)][0].Value
+		hi := hiOp.Data[i%len(hiOp.Data)][0].Value
+
+		var loU, hiU uint64
+		loU, err = ast.DecimalToUint64(lo)
+		if err != nil {
+			return
+		}
+		hiU, err = ast.DecimalToUint64(hi)
+		if err != nil {
+			return
+		}
+		if hiU <= loU {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+		span := hiU - loU
+
+		limit := ^uint64(0) / span * span
+		var v uint64
+		for {
+			binaryUsedIndex := make([]byte, binary.MaxVarintLen64)
+			binary.PutUvarint(binaryUsedIndex, ctx.RandCallIndex)
+			ctx.RandCallIndex++
+
+			hash := ctx.RandSeed(
+				ctx.Randomness,
+				ctx.Origin.Bytes(),
+				binaryOriginNonce,
+				binaryUsedIndex)
+			v = binary.BigEndian.Uint64(hash[:8])
+			if v < limit {
+				break
+			}
+		}
+
+		result.Data[i] = Tuple{&Raw{
+			Value: decimal.NewFromBigInt(new(big.Int).SetUint64(loU+v%span), 0),
+		}}
+	}
+	return
+}
+
 func metaBitOp(dType ast.DataType) bool {
 	dMajor, _ := ast.DecomposeDataType(dType)
 	switch dMajor {
@@ -275,6 +544,20 @@ func metaBitOp(dType ast.DataType) bool {
 
 func metaAllBitOp(op *Operand) bool { return metaAll(op, metaBitOp) }
 
+// metaBytesLike reports whether dType is a DynamicBytes or FixedBytes
+// type, the shared input contract for built-ins documented to accept
+// either a dynbytes column or a bytes32/fixedbytes literal.
+func metaBytesLike(dType ast.DataType) bool {
+	dMajor, _ := ast.DecomposeDataType(dType)
+	switch dMajor {
+	case ast.DataTypeMajorDynamicBytes, ast.DataTypeMajorFixedBytes:
+		return true
+	}
+	return false
+}
+
+func metaAllBytesLike(op *Operand) bool { return metaAll(op, metaBytesLike) }
+
 func extractOps(ops []*Operand) (n int, op1, op2 *Operand, err error) {
 	if len(ops) < 2 {
 		err = se.ErrorCodeInvalidOperandNum
@@ -294,6 +577,150 @@ func extractOps(ops []*Operand) (n int, op1, op2 *Operand, err error) {
 	return
 }
 
+// extractShiftOps is extractOps relaxed for shift/rotate built-ins: the
+// second operand is the shift amount and need not share op1's meta.
+func extractShiftOps(ops []*Operand) (n int, op1, shiftOp *Operand, err error) {
+	if len(ops) != 2 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	n, err = findMaxDataLength(ops)
+	if err != nil {
+		return
+	}
+
+	op1, shiftOp = ops[0], ops[1]
+	if !metaAllBitOp(op1) {
+		err = se.ErrorCodeInvalidDataType
+	}
+	return
+}
+
+// shiftCompute implements one of SHL/SHR/SAR/ROL/ROR over the unsigned
+// width-bit value x; signExtend is true only when the operand's major type
+// is signed and its top bit is set, per EIP-145 semantics.
+type shiftCompute func(x *big.Int, width, shift uint, signExtend bool) *big.Int
+
+func shlCompute(x *big.Int, width, shift uint, signExtend bool) *big.Int {
+	if shift >= width {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Lsh(x, shift)
+}
+
+func shrCompute(x *big.Int, width, shift uint, signExtend bool) *big.Int {
+	if shift >= width {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Rsh(x, shift)
+}
+
+func sarCompute(x *big.Int, width, shift uint, signExtend bool) *big.Int {
+	if !signExtend {
+		return shrCompute(x, width, shift, false)
+	}
+	if shift >= width {
+		return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), width), big.NewInt(1))
+	}
+	signedVal := new(big.Int).Sub(x, new(big.Int).Lsh(big.NewInt(1), width))
+	return new(big.Int).Rsh(signedVal, shift)
+}
+
+func rolCompute(x *big.Int, width, shift uint, signExtend bool) *big.Int {
+	s := shift % width
+	if s == 0 {
+		return new(big.Int).Set(x)
+	}
+	left := new(big.Int).Lsh(x, s)
+	right := new(big.Int).Rsh(x, width-s)
+	return new(big.Int).Or(left, right)
+}
+
+func rorCompute(x *big.Int, width, shift uint, signExtend bool) *big.Int {
+	s := shift % width
+	if s == 0 {
+		return new(big.Int).Set(x)
+	}
+	right := new(big.Int).Rsh(x, s)
+	left := new(big.Int).Lsh(x, width-s)
+	return new(big.Int).Or(left, right)
+}
+
+// fnShiftRotate threads op1's per-row, per-column values (reusing the
+// existing Raw.toBytes/fromBytes conversion so it works uniformly for
+// Uint, Int and FixedBytes) through compute; the shift amount is a second
+// operand, scalar or aligned with op1 via broadcastUint64.
+func fnShiftRotate(in Instruction, compute shiftCompute) (result *Operand, err error) {
+	n, op1, shiftOp, err := extractShiftOps(in.Input)
+	if err != nil {
+		return
+	}
+
+	shifts, err := shiftOp.toUint64()
+	if err != nil {
+		return
+	}
+	if len(shifts) != 1 && len(shifts) != n {
+		err = se.ErrorCodeIndexOutOfRange
+		return
+	}
+
+	result = op1.clone(true)
+	result.Data = make([]Tuple, n)
+	for i := 0; i < n; i++ {
+		var shift uint64
+		shift, err = broadcastUint64(shifts, n, i)
+		if err != nil {
+			return
+		}
+
+		result.Data[i] = make(Tuple, len(op1.Data[i]))
+		for j := range op1.Data[i] {
+			dType := op1.Meta[j]
+			dMajor, _ := ast.DecomposeDataType(dType)
+
+			b := op1.Data[i][j].toBytes(dType)
+			width := uint(len(b) * 8)
+			x := new(big.Int).SetBytes(b)
+			signExtend := dMajor == ast.DataTypeMajorInt && len(b) > 0 && b[0]&0x80 != 0
+
+			y := compute(x, width, uint(shift), signExtend)
+			mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), width), big.NewInt(1))
+			y.And(y, mask)
+
+			out := make([]byte, len(b))
+			yb := y.Bytes()
+			copy(out[len(out)-len(yb):], yb)
+
+			r2 := &Raw{}
+			r2.fromBytes(out, dType)
+			result.Data[i][j] = r2
+		}
+	}
+	return
+}
+
+func fnSHL(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnShiftRotate(in, shlCompute)
+}
+
+func fnSHR(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnShiftRotate(in, shrCompute)
+}
+
+func fnSAR(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnShiftRotate(in, sarCompute)
+}
+
+func fnROL(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnShiftRotate(in, rolCompute)
+}
+
+func fnROR(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnShiftRotate(in, rorCompute)
+}
+
 func (r *Raw) toBytes(dType ast.DataType) []byte {
 	dMajor, _ := ast.DecomposeDataType(dType)
 	switch dMajor {
@@ -463,83 +890,862 @@ func (r *Raw) bitUnOp(dType ast.DataType, bFn bitUnFunc) (r2 *Raw) {
 	return
 }
 
-func fnOctetLength(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
-	if len(in.Input) < 1 {
+// fnHashRowWise applies hashFn to the bytes of every row of a single
+// dynbytes/fixedbytes operand, returning a fixedbytes32 column. It follows
+// the same per-row shape as fnOctetLength.
+func fnHashRowWise(in Instruction, hashFn func([]byte) []byte) (result *Operand, err error) {
+	if len(in.Input) != 1 {
 		err = se.ErrorCodeInvalidOperandNum
 		return
 	}
 
 	op := in.Input[0]
-
-	if !metaAllDynBytes(op) {
+	if !metaAllBytesLike(op) {
 		err = se.ErrorCodeInvalidDataType
 		return
 	}
 
+	fixedBytes32 := ast.ComposeDataType(ast.DataTypeMajorFixedBytes, 31)
 	result = &Operand{
 		Meta: make([]ast.DataType, len(op.Meta)),
 		Data: make([]Tuple, len(op.Data)),
 	}
-
-	uint256Type := ast.ComposeDataType(ast.DataTypeMajorUint, 32)
-	for i := 0; i < len(op.Meta); i++ {
-		result.Meta[i] = uint256Type
+	for i := range op.Meta {
+		result.Meta[i] = fixedBytes32
 	}
 
 	for i := 0; i < len(op.Data); i++ {
 		result.Data[i] = make(Tuple, len(op.Data[i]))
 		for j := 0; j < len(op.Data[i]); j++ {
-			result.Data[i][j] = &Raw{Value: decimal.New(int64(len(op.Data[i][j].Bytes)), 0)}
+			result.Data[i][j] = &Raw{Bytes: hashFn(op.Data[i][j].Bytes)}
 		}
 	}
 	return
 }
 
-func fnSubString(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
-	if len(in.Input) < 3 {
-		err = se.ErrorCodeInvalidOperandNum
-		return
-	}
+func fnKeccak256(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnHashRowWise(in, crypto.Keccak256)
+}
 
-	op := in.Input[0]
+func fnSHA256(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnHashRowWise(in, func(b []byte) []byte {
+		h := sha256.Sum256(b)
+		return h[:]
+	})
+}
 
-	if !metaAllDynBytes(op) {
-		err = se.ErrorCodeInvalidDataType
+func fnRIPEMD160(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnHashRowWise(in, func(b []byte) []byte {
+		h := ripemd160.New()
+		h.Write(b) // nolint: errcheck
+		// Left-pad the 20-byte digest to 32 bytes, as EVM precompile 0x03
+		// does, so every value matches the fixedbytes32 result type.
+		out := make([]byte, 32)
+		copy(out[12:], h.Sum(nil))
+		return out
+	})
+}
+
+// gasWordCost returns a GasFunction charging base+word*ceil(len/32) gas for
+// the single dynbytes/fixedbytes operand of a row-wise hash built-in,
+// mirroring the corresponding EVM opcode/precompile cost.
+func gasWordCost(base, word uint64) GasFunction {
+	return func(ctx *common.Context, in Instruction, length uint64) (uint64, error) {
+		if len(in.Input) != 1 {
+			return 0, se.ErrorCodeInvalidOperandNum
+		}
+		op := in.Input[0]
+		var total uint64
+		for i := 0; i < len(op.Data); i++ {
+			for j := 0; j < len(op.Data[i]); j++ {
+				n := uint64(len(op.Data[i][j].Bytes))
+				total += base + word*((n+31)/32)
+			}
+		}
+		return total, nil
 	}
+}
 
-	result = &Operand{
-		Meta: make([]ast.DataType, len(op.Meta)),
-		Data: make([]Tuple, len(op.Data)),
+var (
+	gasKeccak256 = gasWordCost(GasKeccak256, GasKeccak256Word)
+	gasSHA256    = gasWordCost(GasSHA256, GasSHA256Word)
+	gasRIPEMD160 = gasWordCost(GasRIPEMD160, GasRIPEMD160Word)
+)
+
+// fnECRecover recovers the signer address of (hash, v, r, s), matching EVM
+// precompile 0x01: an invalid signature yields the zero address rather
+// than an error.
+func fnECRecover(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 4 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
 	}
 
-	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
-	for i := 0; i < len(op.Meta); i++ {
-		result.Meta[i] = dynBytesType
+	hashOp, vOp, rOp, sOp := in.Input[0], in.Input[1], in.Input[2], in.Input[3]
+	if !metaAllBytesLike(hashOp) || !metaAllBytesLike(rOp) || !metaAllBytesLike(sOp) {
+		err = se.ErrorCodeInvalidDataType
+		return
 	}
 
-	starts, err := in.Input[1].toUint64()
-	if err == nil && len(starts) != 1 {
+	n := len(hashOp.Data)
+	if (len(rOp.Data) != 1 && len(rOp.Data) != n) || (len(sOp.Data) != 1 && len(sOp.Data) != n) {
 		err = se.ErrorCodeIndexOutOfRange
+		return
 	}
+
+	addressType := ast.ComposeDataType(ast.DataTypeMajorAddress, 0)
+	result = &Operand{Meta: []ast.DataType{addressType}, Data: make([]Tuple, n)}
+
+	vs, err := vOp.toUint64()
 	if err != nil {
 		return
 	}
 
-	lens, err := in.Input[2].toUint64()
-	if err == nil && len(lens) != 1 {
-		err = se.ErrorCodeIndexOutOfRange
+	for i := range hashOp.Data {
+		var v uint64
+		v, err = broadcastUint64(vs, n, i)
+		if err != nil {
+			return
+		}
+
+		sig := make([]byte, 65)
+		copy(sig[0:32], rOp.Data[i%len(rOp.Data)][0].Bytes)
+		copy(sig[32:64], sOp.Data[i%len(sOp.Data)][0].Bytes)
+		if v >= 27 {
+			v -= 27
+		}
+		sig[64] = byte(v)
+
+		addr := make([]byte, 20)
+		if pub, e := crypto.SigToPub(hashOp.Data[i][0].Bytes, sig); e == nil {
+			addr = crypto.PubkeyToAddress(*pub).Bytes()
+		}
+		result.Data[i] = Tuple{&Raw{Bytes: addr}}
 	}
-	if err != nil {
+	return
+}
+
+func fnOctetLength(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) < 1 {
+		err = se.ErrorCodeInvalidOperandNum
 		return
 	}
 
-	start, end := starts[0], starts[0]+lens[0]
+	op := in.Input[0]
 
-	for i := 0; i < len(op.Data); i++ {
-		result.Data[i] = make(Tuple, len(op.Data[i]))
-		for j := 0; j < len(op.Data[i]); j++ {
-			result.Data[i][j] = &Raw{Bytes: op.Data[i][j].Bytes[start:end]}
-		}
+	if !metaAllDynBytes(op) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	result = &Operand{
+		Meta: make([]ast.DataType, len(op.Meta)),
+		Data: make([]Tuple, len(op.Data)),
 	}
+
+	uint256Type := ast.ComposeDataType(ast.DataTypeMajorUint, 32)
+	for i := 0; i < len(op.Meta); i++ {
+		result.Meta[i] = uint256Type
+	}
+
+	for i := 0; i < len(op.Data); i++ {
+		result.Data[i] = make(Tuple, len(op.Data[i]))
+		for j := 0; j < len(op.Data[i]); j++ {
+			result.Data[i][j] = &Raw{Value: decimal.New(int64(len(op.Data[i][j].Bytes)), 0)}
+		}
+	}
+	return
+}
+
+// broadcastUint64 returns vals[i] when vals carries one value per row, or
+// the single scalar vals[0] when it was supplied as an immediate, matching
+// the broadcast semantics used by the bitwise built-ins.
+func broadcastUint64(vals []uint64, n, i int) (uint64, error) {
+	switch len(vals) {
+	case 1:
+		return vals[0], nil
+	case n:
+		return vals[i], nil
+	default:
+		return 0, se.ErrorCodeIndexOutOfRange
+	}
+}
+
+// fnSubString implements SQL-standard SUBSTRING(str, start [, length]),
+// where start/length are 1-based and may each be either a scalar or a
+// column aligned with str. Out-of-range indices clamp rather than panic;
+// only negative/overflowing arithmetic is rejected.
+func fnSubString(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 2 && len(in.Input) != 3 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op := in.Input[0]
+	if !metaAllDynBytes(op) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	n := len(op.Data)
+
+	starts, err := in.Input[1].toUint64()
+	if err != nil {
+		return
+	}
+	if len(starts) != 1 && len(starts) != n {
+		err = se.ErrorCodeIndexOutOfRange
+		return
+	}
+
+	hasLen := len(in.Input) == 3
+	var lens []uint64
+	if hasLen {
+		lens, err = in.Input[2].toUint64()
+		if err != nil {
+			return
+		}
+		if len(lens) != 1 && len(lens) != n {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+	}
+
+	result = &Operand{
+		Meta: make([]ast.DataType, len(op.Meta)),
+		Data: make([]Tuple, n),
+	}
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	for i := range op.Meta {
+		result.Meta[i] = dynBytesType
+	}
+
+	for i := 0; i < n; i++ {
+		start, e := broadcastUint64(starts, n, i)
+		if e != nil {
+			err = e
+			return
+		}
+		if start < 1 {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+
+		result.Data[i] = make(Tuple, len(op.Data[i]))
+		for j := 0; j < len(op.Data[i]); j++ {
+			total := uint64(len(op.Data[i][j].Bytes))
+
+			var sliced []byte
+			if start > total {
+				sliced = []byte{}
+			} else {
+				avail := total - (start - 1)
+				if hasLen {
+					l, e := broadcastUint64(lens, n, i)
+					if e != nil {
+						err = e
+						return
+					}
+					if l < avail {
+						avail = l
+					}
+				}
+				sliced = op.Data[i][j].Bytes[start-1 : start-1+avail]
+			}
+			result.Data[i][j] = &Raw{Bytes: append([]byte{}, sliced...)}
+		}
+	}
+	return
+}
+
+// fnConcat concatenates two or more dynbytes columns row by row.
+func fnConcat(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) < 2 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+	for _, op := range in.Input {
+		if !metaAllDynBytes(op) {
+			err = se.ErrorCodeInvalidDataType
+			return
+		}
+	}
+
+	n, err := findMaxDataLength(in.Input)
+	if err != nil {
+		return
+	}
+
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	result = &Operand{Meta: []ast.DataType{dynBytesType}, Data: make([]Tuple, n)}
+	for i := 0; i < n; i++ {
+		var out []byte
+		for _, op := range in.Input {
+			out = append(out, op.Data[i%len(op.Data)][0].Bytes...)
+		}
+		result.Data[i] = Tuple{&Raw{Bytes: out}}
+	}
+	return
+}
+
+// fnPad implements LPAD/RPAD(str, len [, padstr]): str is truncated to len
+// bytes, or padded with padstr (repeated as needed, zero bytes by default,
+// the same convention used when producing fixed-width ABI fields) on the
+// given side until it reaches len bytes.
+func fnPad(in Instruction, left bool) (result *Operand, err error) {
+	if len(in.Input) != 2 && len(in.Input) != 3 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op := in.Input[0]
+	if !metaAllDynBytes(op) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	n := len(op.Data)
+	lens, err := in.Input[1].toUint64()
+	if err != nil {
+		return
+	}
+	if len(lens) != 1 && len(lens) != n {
+		err = se.ErrorCodeIndexOutOfRange
+		return
+	}
+
+	pad := []byte{0x00}
+	if len(in.Input) == 3 {
+		padOp := in.Input[2]
+		if !metaAllDynBytes(padOp) || len(padOp.Data) == 0 || len(padOp.Data[0][0].Bytes) == 0 {
+			err = se.ErrorCodeInvalidDataType
+			return
+		}
+		pad = padOp.Data[0][0].Bytes
+	}
+
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	result = &Operand{Meta: []ast.DataType{dynBytesType}, Data: make([]Tuple, n)}
+
+	for i := 0; i < n; i++ {
+		total, e := broadcastUint64(lens, n, i)
+		if e != nil {
+			err = e
+			return
+		}
+
+		src := op.Data[i][0].Bytes
+		var out []byte
+		if uint64(len(src)) >= total {
+			if left {
+				out = src[uint64(len(src))-total:]
+			} else {
+				out = src[:total]
+			}
+		} else {
+			fill := make([]byte, total-uint64(len(src)))
+			for k := range fill {
+				fill[k] = pad[k%len(pad)]
+			}
+			if left {
+				out = append(fill, src...)
+			} else {
+				out = append(append([]byte{}, src...), fill...)
+			}
+		}
+		result.Data[i] = Tuple{&Raw{Bytes: append([]byte{}, out...)}}
+	}
+	return
+}
+
+func fnLPad(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnPad(in, true)
+}
+
+func fnRPad(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnPad(in, false)
+}
+
+// fnCase applies caseFn to every byte of a dynbytes column, for UPPER/LOWER.
+func fnCase(in Instruction, caseFn func([]byte) []byte) (result *Operand, err error) {
+	if len(in.Input) != 1 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op := in.Input[0]
+	if !metaAllDynBytes(op) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	result = &Operand{
+		Meta: make([]ast.DataType, len(op.Meta)),
+		Data: make([]Tuple, len(op.Data)),
+	}
+	for i := range op.Meta {
+		result.Meta[i] = dynBytesType
+	}
+
+	for i := range op.Data {
+		result.Data[i] = make(Tuple, len(op.Data[i]))
+		for j := range op.Data[i] {
+			result.Data[i][j] = &Raw{Bytes: caseFn(op.Data[i][j].Bytes)}
+		}
+	}
+	return
+}
+
+func fnUpper(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnCase(in, bytes.ToUpper)
+}
+
+func fnLower(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnCase(in, bytes.ToLower)
+}
+
+// fnReplace implements REPLACE(str, from, to): every non-overlapping
+// occurrence of from is replaced by to, row by row.
+func fnReplace(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 3 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op, fromOp, toOp := in.Input[0], in.Input[1], in.Input[2]
+	if !metaAllDynBytes(op) || !metaAllDynBytes(fromOp) || !metaAllDynBytes(toOp) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	n := len(op.Data)
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	result = &Operand{Meta: []ast.DataType{dynBytesType}, Data: make([]Tuple, n)}
+
+	for i := 0; i < n; i++ {
+		from := fromOp.Data[i%len(fromOp.Data)][0].Bytes
+		to := toOp.Data[i%len(toOp.Data)][0].Bytes
+		out := bytes.Replace(op.Data[i][0].Bytes, from, to, -1)
+		result.Data[i] = Tuple{&Raw{Bytes: out}}
+	}
+	return
+}
+
+// abiWordSize is the width, in bytes, of every head slot and of dynamic
+// tail alignment in the Solidity ABI encoding.
+const abiWordSize = 32
+
+// abiPadLeftWord left-pads b to a 32-byte head word. When signExtend is
+// true (a negative Int narrower than 32 bytes) the fill byte is 0xFF,
+// matching Solidity's two's-complement sign extension, instead of 0x00.
+func abiPadLeftWord(b []byte, signExtend bool) []byte {
+	out := make([]byte, abiWordSize)
+	if signExtend {
+		for i := range out {
+			out[i] = 0xFF
+		}
+	}
+	if len(b) > abiWordSize {
+		b = b[len(b)-abiWordSize:]
+	}
+	copy(out[abiWordSize-len(b):], b)
+	return out
+}
+
+// abiNarrowHead right-aligns a 32-byte head word down to a type's own
+// native byte width before decoding, the inverse of abiPadLeftWord.
+func abiNarrowHead(head []byte, width int) []byte {
+	return head[abiWordSize-width:]
+}
+
+func abiPadRightWord(b []byte) []byte {
+	out := make([]byte, abiWordSize)
+	copy(out, b)
+	return out
+}
+
+// abiAlign right-pads b with zero bytes until its length is a multiple of
+// abiWordSize, as required for the tail of a dynamic ABI value.
+func abiAlign(b []byte) []byte {
+	pad := (abiWordSize - len(b)%abiWordSize) % abiWordSize
+	if pad == 0 {
+		return b
+	}
+	return append(b, make([]byte, pad)...)
+}
+
+func abiIsDynamicType(dType ast.DataType) bool {
+	dMajor, _ := ast.DecomposeDataType(dType)
+	return dMajor == ast.DataTypeMajorDynamicBytes
+}
+
+// abiEncodeValue produces the head word for a static value, or the tail
+// bytes (32-byte length prefix followed by word-aligned content) for a
+// dynamic one.
+func abiEncodeValue(r *Raw, dType ast.DataType) (head, tail []byte, err error) {
+	dMajor, _ := ast.DecomposeDataType(dType)
+	switch dMajor {
+	case ast.DataTypeMajorUint, ast.DataTypeMajorFixed, ast.DataTypeMajorAddress:
+		head = abiPadLeftWord(r.toBytes(dType), false)
+	case ast.DataTypeMajorInt:
+		raw := r.toBytes(dType)
+		negative := len(raw) > 0 && raw[0]&0x80 != 0
+		head = abiPadLeftWord(raw, negative)
+	case ast.DataTypeMajorFixedBytes:
+		head = abiPadRightWord(r.toBytes(dType))
+	case ast.DataTypeMajorDynamicBytes:
+		length := make([]byte, abiWordSize)
+		binary.BigEndian.PutUint64(length[abiWordSize-8:], uint64(len(r.Bytes)))
+		tail = append(length, abiAlign(r.Bytes)...)
+	default:
+		err = se.ErrorCodeInvalidDataType
+	}
+	return
+}
+
+// abiEncodeRow packs one row of typed operands into a single dynbytes
+// value following the standard head/tail ABI tuple layout: the head
+// section holds either the value (static types) or the byte offset of the
+// tail (dynamic types), and tails are appended in input order.
+func abiEncodeRow(ops []*Operand, row int) (out []byte, err error) {
+	heads := make([][]byte, len(ops))
+	tails := make([][]byte, len(ops))
+
+	for i, op := range ops {
+		r := op.Data[row%len(op.Data)][0]
+		var head, tail []byte
+		head, tail, err = abiEncodeValue(r, op.Meta[0])
+		if err != nil {
+			return
+		}
+		heads[i], tails[i] = head, tail
+	}
+
+	headSize := len(ops) * abiWordSize
+	var tailBuf []byte
+	for i, op := range ops {
+		if abiIsDynamicType(op.Meta[0]) {
+			offset := make([]byte, abiWordSize)
+			binary.BigEndian.PutUint64(offset[abiWordSize-8:], uint64(headSize+len(tailBuf)))
+			heads[i] = offset
+			tailBuf = append(tailBuf, tails[i]...)
+		}
+	}
+
+	out = make([]byte, 0, headSize+len(tailBuf))
+	for _, h := range heads {
+		out = append(out, h...)
+	}
+	out = append(out, tailBuf...)
+	return
+}
+
+func fnABIEncode(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) < 1 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	n, err := findMaxDataLength(in.Input)
+	if err != nil {
+		return
+	}
+
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	result = &Operand{Meta: []ast.DataType{dynBytesType}, Data: make([]Tuple, n)}
+
+	for row := 0; row < n; row++ {
+		var packed []byte
+		packed, err = abiEncodeRow(in.Input, row)
+		if err != nil {
+			return
+		}
+		result.Data[row] = Tuple{&Raw{Bytes: packed}}
+	}
+	return
+}
+
+// abiDecodeValue reads one head-aligned field out of bytes for dType,
+// following the tail for dynamic types.
+func abiDecodeValue(bytes, head []byte, offset int, dType ast.DataType) (r *Raw, err error) {
+	dMajor, dMinor := ast.DecomposeDataType(dType)
+	switch dMajor {
+	case ast.DataTypeMajorUint, ast.DataTypeMajorInt, ast.DataTypeMajorFixed:
+		nativeWidth := dMinor + 1
+		if nativeWidth > abiWordSize {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+		var v decimal.Decimal
+		v, err = ast.DecimalDecode(dType, abiNarrowHead(head, nativeWidth))
+		if err != nil {
+			return
+		}
+		r = &Raw{Value: v}
+	case ast.DataTypeMajorAddress:
+		r = &Raw{Bytes: append([]byte{}, head[abiWordSize-20:]...)}
+	case ast.DataTypeMajorFixedBytes:
+		n := dMinor + 1
+		if n > abiWordSize {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+		r = &Raw{Bytes: append([]byte{}, head[:n]...)}
+	case ast.DataTypeMajorDynamicBytes:
+		if uint64(len(bytes)) < abiWordSize {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+		tailOffset := binary.BigEndian.Uint64(head[abiWordSize-8:])
+		if tailOffset > uint64(len(bytes))-abiWordSize {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+		l := binary.BigEndian.Uint64(bytes[tailOffset+abiWordSize-8 : tailOffset+abiWordSize])
+		start := tailOffset + abiWordSize
+		if l > uint64(len(bytes))-start {
+			err = se.ErrorCodeIndexOutOfRange
+			return
+		}
+		end := start + l
+		r = &Raw{Bytes: append([]byte{}, bytes[start:end]...)}
+	default:
+		err = se.ErrorCodeInvalidDataType
+	}
+	return
+}
+
+func abiDecodeRow(bytes []byte, types []ast.DataType) (t Tuple, err error) {
+	headSize := len(types) * abiWordSize
+	if len(bytes) < headSize {
+		err = se.ErrorCodeIndexOutOfRange
+		return
+	}
+
+	t = make(Tuple, len(types))
+	for i, dType := range types {
+		head := bytes[i*abiWordSize : (i+1)*abiWordSize]
+		t[i], err = abiDecodeValue(bytes, head, i*abiWordSize, dType)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// fnABIDecode unpacks a dynbytes column according to the type descriptors
+// carried by in.Input[1:]; only the Meta of each descriptor operand is
+// used, its Data is ignored.
+func fnABIDecode(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) < 2 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	data := in.Input[0]
+	if !metaAllDynBytes(data) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	types := make([]ast.DataType, len(in.Input)-1)
+	for i, op := range in.Input[1:] {
+		types[i] = op.Meta[0]
+	}
+
+	result = &Operand{Meta: types, Data: make([]Tuple, len(data.Data))}
+	for row := range data.Data {
+		result.Data[row], err = abiDecodeRow(data.Data[row][0].Bytes, types)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// gasAggregate charges a base cost plus a per-row cost for consuming the
+// first operand's column, shared by every aggregate built-in.
+var gasAggregate = gasRows(GasAggregateBase, GasAggregatePerRow)
+
+func gasRows(base, perRow uint64) GasFunction {
+	return func(ctx *common.Context, in Instruction, length uint64) (uint64, error) {
+		if len(in.Input) < 1 {
+			return 0, se.ErrorCodeInvalidOperandNum
+		}
+		return base + perRow*uint64(len(in.Input[0].Data)), nil
+	}
+}
+
+func metaAggEligible(dType ast.DataType) bool {
+	dMajor, _ := ast.DecomposeDataType(dType)
+	switch dMajor {
+	case ast.DataTypeMajorUint, ast.DataTypeMajorInt, ast.DataTypeMajorFixed:
+		return true
+	}
+	return false
+}
+
+// fnSum implements SUM(col), promoting Uint/Int accumulators to their
+// 256-bit width to avoid overflow; Fixed is summed in place since its
+// decimal representation is already arbitrary precision.
+func fnSum(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 1 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op := in.Input[0]
+	if !metaAggEligible(op.Meta[0]) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	resultType := op.Meta[0]
+	switch dMajor, _ := ast.DecomposeDataType(op.Meta[0]); dMajor {
+	case ast.DataTypeMajorUint:
+		resultType = ast.ComposeDataType(ast.DataTypeMajorUint, 31)
+	case ast.DataTypeMajorInt:
+		resultType = ast.ComposeDataType(ast.DataTypeMajorInt, 31)
+	}
+
+	sum := decimal.New(0, 0)
+	for i := range op.Data {
+		sum = sum.Add(op.Data[i][0].Value)
+	}
+
+	result = &Operand{Meta: []ast.DataType{resultType}, Data: []Tuple{{&Raw{Value: sum}}}}
+	return
+}
+
+// fnCount implements COUNT(col), counting rows regardless of type.
+func fnCount(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 1 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	uint256Type := ast.ComposeDataType(ast.DataTypeMajorUint, 31)
+	n := decimal.New(int64(len(in.Input[0].Data)), 0)
+	result = &Operand{Meta: []ast.DataType{uint256Type}, Data: []Tuple{{&Raw{Value: n}}}}
+	return
+}
+
+// fnAvg implements AVG(col) as SUM(col)/COUNT(col), dividing at a fixed
+// avgScale-digit scale via decimal.DivRound.
+func fnAvg(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 1 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op := in.Input[0]
+	if !metaAggEligible(op.Meta[0]) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+	if len(op.Data) == 0 {
+		err = se.ErrorCodeIndexOutOfRange
+		return
+	}
+
+	sum := decimal.New(0, 0)
+	for i := range op.Data {
+		sum = sum.Add(op.Data[i][0].Value)
+	}
+	avg := sum.DivRound(decimal.New(int64(len(op.Data)), 0), avgScale)
+
+	fixedType := ast.ComposeDataType(ast.DataTypeMajorFixed, 31)
+	result = &Operand{Meta: []ast.DataType{fixedType}, Data: []Tuple{{&Raw{Value: avg}}}}
+	return
+}
+
+// rawCompare orders two Raw values of the same dType: numerically for
+// Uint/Int/Fixed, lexicographically for FixedBytes/DynamicBytes/Address.
+func rawCompare(r1, r2 *Raw, dType ast.DataType) int {
+	dMajor, _ := ast.DecomposeDataType(dType)
+	switch dMajor {
+	case ast.DataTypeMajorUint, ast.DataTypeMajorInt, ast.DataTypeMajorFixed:
+		return r1.Value.Cmp(r2.Value)
+	case ast.DataTypeMajorFixedBytes, ast.DataTypeMajorDynamicBytes, ast.DataTypeMajorAddress:
+		return bytes.Compare(r1.Bytes, r2.Bytes)
+	default:
+		panic(fmt.Errorf("unrecognized data type: %v", dType))
+	}
+}
+
+// fnMinMax implements MIN/MAX(col) over every bitwise-eligible major type
+// plus Fixed (numeric comparison) and DynamicBytes (lexicographic).
+func fnMinMax(in Instruction, wantMax bool) (result *Operand, err error) {
+	if len(in.Input) != 1 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op := in.Input[0]
+	if len(op.Data) == 0 {
+		err = se.ErrorCodeIndexOutOfRange
+		return
+	}
+
+	dMajor, _ := ast.DecomposeDataType(op.Meta[0])
+	switch dMajor {
+	case ast.DataTypeMajorUint, ast.DataTypeMajorInt, ast.DataTypeMajorFixedBytes,
+		ast.DataTypeMajorFixed, ast.DataTypeMajorDynamicBytes, ast.DataTypeMajorAddress:
+	default:
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+
+	best := op.Data[0][0]
+	for i := 1; i < len(op.Data); i++ {
+		cur := op.Data[i][0]
+		cmp := rawCompare(cur, best, op.Meta[0])
+		if (wantMax && cmp > 0) || (!wantMax && cmp < 0) {
+			best = cur
+		}
+	}
+
+	result = &Operand{Meta: []ast.DataType{op.Meta[0]}, Data: []Tuple{{best.clone()}}}
+	return
+}
+
+func fnMin(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnMinMax(in, false)
+}
+
+func fnMax(ctx *common.Context, in Instruction, length uint64) (*Operand, error) {
+	return fnMinMax(in, true)
+}
+
+// fnGroupConcat implements GROUPCONCAT(col, sep), joining every row of a
+// dynbytes column with sep.
+func fnGroupConcat(ctx *common.Context, in Instruction, length uint64) (result *Operand, err error) {
+	if len(in.Input) != 2 {
+		err = se.ErrorCodeInvalidOperandNum
+		return
+	}
+
+	op, sepOp := in.Input[0], in.Input[1]
+	if !metaAllDynBytes(op) || !metaAllDynBytes(sepOp) {
+		err = se.ErrorCodeInvalidDataType
+		return
+	}
+	if len(sepOp.Data) == 0 {
+		err = se.ErrorCodeIndexOutOfRange
+		return
+	}
+
+	sep := sepOp.Data[0][0].Bytes
+	var out []byte
+	for i := range op.Data {
+		if i > 0 {
+			out = append(out, sep...)
+		}
+		out = append(out, op.Data[i][0].Bytes...)
+	}
+
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	result = &Operand{Meta: []ast.DataType{dynBytesType}, Data: []Tuple{{&Raw{Bytes: out}}}}
 	return
 }
@@ -0,0 +1,300 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/dexon-foundation/decimal"
+
+	"github.com/dexon-foundation/dexon/core/vm/sqlvm/ast"
+	"github.com/dexon-foundation/dexon/core/vm/sqlvm/common"
+	se "github.com/dexon-foundation/dexon/core/vm/sqlvm/errors"
+)
+
+func TestSarComputeSignExtendsNegative(t *testing.T) {
+	// int8(-1) stored unsigned is 0xFF; SAR(int8(-1), 1) must stay -1, the
+	// same all-ones sign extension sarCompute shares with the EVM's SAR.
+	x := big.NewInt(0xFF)
+	got := sarCompute(x, 8, 1, true)
+	if got.Cmp(big.NewInt(-1)) != 0 {
+		t.Errorf("sarCompute(0xFF, width=8, shift=1, signExtend=true) = %v, want -1", got)
+	}
+
+	// Shifting by at least the width saturates to all-ones for a negative
+	// value, i.e. -1 again.
+	got = sarCompute(x, 8, 8, true)
+	want := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 8), big.NewInt(1))
+	if got.Cmp(want) != 0 {
+		t.Errorf("sarCompute(0xFF, width=8, shift=8, signExtend=true) = %v, want %v", got, want)
+	}
+}
+
+func TestSarComputeMatchesShrForUnsigned(t *testing.T) {
+	x := big.NewInt(0x80)
+	got := sarCompute(x, 8, 1, false)
+	want := shrCompute(x, 8, 1, false)
+	if got.Cmp(want) != 0 {
+		t.Errorf("sarCompute(signExtend=false) = %v, want %v (same as shrCompute)", got, want)
+	}
+}
+
+func TestRolRorAtShiftEqualsWidth(t *testing.T) {
+	x := big.NewInt(0x81)
+	if got := rolCompute(x, 8, 8, false); got.Cmp(x) != 0 {
+		t.Errorf("rolCompute(shift==width) = %v, want unchanged %v", got, x)
+	}
+	if got := rorCompute(x, 8, 8, false); got.Cmp(x) != 0 {
+		t.Errorf("rorCompute(shift==width) = %v, want unchanged %v", got, x)
+	}
+}
+
+// mask8 trims a rolCompute/rorCompute result down to its 8-bit width, the
+// same masking fnShiftRotate applies to the raw OR of the two shifted
+// halves before storing the result back into a fixed-width Raw.
+func mask8(x *big.Int) *big.Int {
+	mask := big.NewInt(0xFF)
+	return new(big.Int).And(x, mask)
+}
+
+func TestRolRorWrapBits(t *testing.T) {
+	// ROL(0b1000_0001, 1) over 8 bits wraps the top bit around to the
+	// bottom: 0b0000_0011.
+	x := big.NewInt(0x81)
+	if got, want := mask8(rolCompute(x, 8, 1, false)), big.NewInt(0x03); got.Cmp(want) != 0 {
+		t.Errorf("rolCompute(0x81, width=8, shift=1) masked = %v, want %v", got, want)
+	}
+	// ROR(0b1000_0001, 1) over 8 bits wraps the bottom bit around to the
+	// top: 0b1100_0000.
+	if got, want := mask8(rorCompute(x, 8, 1, false)), big.NewInt(0xC0); got.Cmp(want) != 0 {
+		t.Errorf("rorCompute(0x81, width=8, shift=1) masked = %v, want %v", got, want)
+	}
+}
+
+func TestAbiPadLeftWordSignExtend(t *testing.T) {
+	negOne := []byte{0xFF} // int8(-1)
+	got := abiPadLeftWord(negOne, true)
+	want := bytes.Repeat([]byte{0xFF}, abiWordSize)
+	if !bytes.Equal(got, want) {
+		t.Errorf("abiPadLeftWord(negOne, true) = %x, want %x", got, want)
+	}
+
+	positive := []byte{0x01}
+	got = abiPadLeftWord(positive, false)
+	want = make([]byte, abiWordSize)
+	want[abiWordSize-1] = 0x01
+	if !bytes.Equal(got, want) {
+		t.Errorf("abiPadLeftWord(positive, false) = %x, want %x", got, want)
+	}
+}
+
+func TestAbiNarrowHead(t *testing.T) {
+	head := make([]byte, abiWordSize)
+	head[abiWordSize-1] = 0x2a
+	got := abiNarrowHead(head, 8)
+	want := make([]byte, 8)
+	want[7] = 0x2a
+	if !bytes.Equal(got, want) {
+		t.Errorf("abiNarrowHead = %x, want %x", got, want)
+	}
+}
+
+func TestAbiDecodeValueRejectsOverflowingOffsetAndLength(t *testing.T) {
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	buf := make([]byte, 1000)
+
+	// tailOffset = 2^64-16: tailOffset+abiWordSize wraps to 16, which
+	// would sail past a naive length check on a 1000-byte buffer.
+	offsetHead := make([]byte, abiWordSize)
+	binary.BigEndian.PutUint64(offsetHead[abiWordSize-8:], ^uint64(0)-15)
+	if _, err := abiDecodeValue(buf, offsetHead, 0, dynBytesType); err != se.ErrorCodeIndexOutOfRange {
+		t.Errorf("abiDecodeValue with overflowing offset = %v, want ErrorCodeIndexOutOfRange", err)
+	}
+
+	// A valid offset but a length field crafted so start+l wraps below
+	// start must not panic by slicing with end < start.
+	lengthHead := make([]byte, abiWordSize)
+	binary.BigEndian.PutUint64(lengthHead[abiWordSize-8:], 0)
+	binary.BigEndian.PutUint64(buf[abiWordSize-8:abiWordSize], ^uint64(0)-15)
+	if _, err := abiDecodeValue(buf, lengthHead, 0, dynBytesType); err != se.ErrorCodeIndexOutOfRange {
+		t.Errorf("abiDecodeValue with overflowing length = %v, want ErrorCodeIndexOutOfRange", err)
+	}
+}
+
+func TestBroadcastUint64RejectsMismatchedLength(t *testing.T) {
+	_, err := broadcastUint64([]uint64{1, 2}, 3, 0)
+	if err != se.ErrorCodeIndexOutOfRange {
+		t.Errorf("broadcastUint64 with mismatched length = %v, want ErrorCodeIndexOutOfRange", err)
+	}
+}
+
+func TestMetaAllBytesLikeAcceptsFixedBytes(t *testing.T) {
+	op := &Operand{Meta: []ast.DataType{ast.ComposeDataType(ast.DataTypeMajorFixedBytes, 31)}}
+	if !metaAllBytesLike(op) {
+		t.Errorf("metaAllBytesLike rejected a FixedBytes operand")
+	}
+	op = &Operand{Meta: []ast.DataType{ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)}}
+	if !metaAllBytesLike(op) {
+		t.Errorf("metaAllBytesLike rejected a DynamicBytes operand")
+	}
+	op = &Operand{Meta: []ast.DataType{ast.ComposeDataType(ast.DataTypeMajorUint, 31)}}
+	if metaAllBytesLike(op) {
+		t.Errorf("metaAllBytesLike accepted a Uint operand")
+	}
+}
+
+type fakeRandSource struct{ seed []byte }
+
+func (f *fakeRandSource) Seed(parts ...[]byte) []byte { return f.seed }
+
+func TestRandSourceIsPluggable(t *testing.T) {
+	ctx := &common.Context{RandSource: &fakeRandSource{seed: []byte("deterministic")}}
+
+	if got := ctx.RandSeed([]byte("anything")); string(got) != "deterministic" {
+		t.Errorf("ctx.RandSeed = %q, want %q", got, "deterministic")
+	}
+}
+
+func TestRandSourceDefaultsToKeccak(t *testing.T) {
+	ctx := &common.Context{}
+
+	want := common.KeccakRandSource{}.Seed([]byte("anything"))
+	if got := ctx.RandSeed([]byte("anything")); string(got) != string(want) {
+		t.Errorf("ctx.RandSeed = %x, want %x (KeccakRandSource default)", got, want)
+	}
+}
+
+func TestFnConcatBroadcastsScalarOperand(t *testing.T) {
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	col := &Operand{
+		Meta: []ast.DataType{dynBytesType},
+		Data: []Tuple{{&Raw{Bytes: []byte("row0")}}, {&Raw{Bytes: []byte("row1")}}},
+	}
+	scalar := &Operand{
+		Meta: []ast.DataType{dynBytesType},
+		Data: []Tuple{{&Raw{Bytes: []byte("-suffix")}}},
+	}
+	in := Instruction{Input: []*Operand{col, scalar}}
+
+	result, err := fnConcat(nil, in, 0)
+	if err != nil {
+		t.Fatalf("fnConcat returned error: %v", err)
+	}
+	if len(result.Data) != 2 {
+		t.Fatalf("fnConcat returned %d rows, want 2", len(result.Data))
+	}
+	if got, want := string(result.Data[0][0].Bytes), "row0-suffix"; got != want {
+		t.Errorf("row 0 = %q, want %q", got, want)
+	}
+	if got, want := string(result.Data[1][0].Bytes), "row1-suffix"; got != want {
+		t.Errorf("row 1 = %q, want %q", got, want)
+	}
+}
+
+func TestAbiEncodeRowBroadcastsScalarOperand(t *testing.T) {
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	col := &Operand{
+		Meta: []ast.DataType{dynBytesType},
+		Data: []Tuple{{&Raw{Bytes: []byte("row0")}}, {&Raw{Bytes: []byte("row1")}}},
+	}
+	scalar := &Operand{
+		Meta: []ast.DataType{dynBytesType},
+		Data: []Tuple{{&Raw{Bytes: []byte("const")}}},
+	}
+	ops := []*Operand{col, scalar}
+
+	for row := 0; row < 2; row++ {
+		out, err := abiEncodeRow(ops, row)
+		if err != nil {
+			t.Fatalf("abiEncodeRow(row=%d) returned error: %v", row, err)
+		}
+		if !bytes.Contains(out, []byte("const")) {
+			t.Errorf("abiEncodeRow(row=%d) = %x, expected broadcast scalar tail to appear", row, out)
+		}
+	}
+}
+
+func TestFnSumPromotesNarrowUintAccumulator(t *testing.T) {
+	// A narrow Uint column (1-byte native width) summing to a value that
+	// wouldn't fit back in that width must still promote its result type
+	// to Uint256, and must not truncate the sum along the way.
+	narrowUint := ast.ComposeDataType(ast.DataTypeMajorUint, 0)
+	op := &Operand{
+		Meta: []ast.DataType{narrowUint},
+		Data: []Tuple{{&Raw{Value: decimal.New(200, 0)}}, {&Raw{Value: decimal.New(200, 0)}}},
+	}
+	in := Instruction{Input: []*Operand{op}}
+
+	result, err := fnSum(nil, in, 0)
+	if err != nil {
+		t.Fatalf("fnSum returned error: %v", err)
+	}
+	if want := ast.ComposeDataType(ast.DataTypeMajorUint, 31); result.Meta[0] != want {
+		t.Errorf("fnSum result type = %v, want Uint256 (%v)", result.Meta[0], want)
+	}
+	if want := decimal.New(400, 0); result.Data[0][0].Value.Cmp(want) != 0 {
+		t.Errorf("fnSum value = %v, want %v", result.Data[0][0].Value, want)
+	}
+}
+
+func TestFnAvgDividesAtFixedScale(t *testing.T) {
+	uintType := ast.ComposeDataType(ast.DataTypeMajorUint, 31)
+	op := &Operand{
+		Meta: []ast.DataType{uintType},
+		Data: []Tuple{{&Raw{Value: decimal.New(10, 0)}}, {&Raw{Value: decimal.New(3, 0)}}},
+	}
+	in := Instruction{Input: []*Operand{op}}
+
+	result, err := fnAvg(nil, in, 0)
+	if err != nil {
+		t.Fatalf("fnAvg returned error: %v", err)
+	}
+	want := decimal.New(13, 0).DivRound(decimal.New(2, 0), avgScale)
+	if result.Data[0][0].Value.Cmp(want) != 0 {
+		t.Errorf("fnAvg value = %v, want %v", result.Data[0][0].Value, want)
+	}
+}
+
+func TestFnMinMaxOverDynamicBytes(t *testing.T) {
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	op := &Operand{
+		Meta: []ast.DataType{dynBytesType},
+		Data: []Tuple{
+			{&Raw{Bytes: []byte("banana")}},
+			{&Raw{Bytes: []byte("apple")}},
+			{&Raw{Bytes: []byte("cherry")}},
+		},
+	}
+	in := Instruction{Input: []*Operand{op}}
+
+	min, err := fnMin(nil, in, 0)
+	if err != nil {
+		t.Fatalf("fnMin returned error: %v", err)
+	}
+	if got, want := string(min.Data[0][0].Bytes), "apple"; got != want {
+		t.Errorf("fnMin = %q, want %q", got, want)
+	}
+
+	max, err := fnMax(nil, in, 0)
+	if err != nil {
+		t.Fatalf("fnMax returned error: %v", err)
+	}
+	if got, want := string(max.Data[0][0].Bytes), "cherry"; got != want {
+		t.Errorf("fnMax = %q, want %q", got, want)
+	}
+}
+
+func TestFnGroupConcatRejectsEmptySeparatorColumn(t *testing.T) {
+	dynBytesType := ast.ComposeDataType(ast.DataTypeMajorDynamicBytes, 0)
+	op := &Operand{
+		Meta: []ast.DataType{dynBytesType},
+		Data: []Tuple{{&Raw{Bytes: []byte("a")}}, {&Raw{Bytes: []byte("b")}}},
+	}
+	emptySep := &Operand{Meta: []ast.DataType{dynBytesType}, Data: []Tuple{}}
+	in := Instruction{Input: []*Operand{op, emptySep}}
+
+	if _, err := fnGroupConcat(nil, in, 0); err != se.ErrorCodeIndexOutOfRange {
+		t.Errorf("fnGroupConcat with empty separator column = %v, want ErrorCodeIndexOutOfRange", err)
+	}
+}